@@ -1,64 +1,94 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
-	"os"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/ritego/middlewares-with-go-and-gorilla/middlewares"
 	"github.com/spf13/viper"
+	"go.uber.org/fx"
 )
 
-var (
-	router *mux.Router
-)
+const defaultDrainTimeout = 10 * time.Second
 
 func main() {
-	initConfig()
-	setupRouter()
-	startServer()
+	fx.New(
+		fx.Provide(
+			NewViper,
+			mux.NewRouter,
+			NewServer,
+		),
+		middlewares.Module,
+		fx.Invoke(RegisterRoutes),
+		// *http.Server is only depended on by its own lifecycle hooks, so it
+		// needs an explicit Invoke to force fx to construct it - otherwise
+		// NewServer, and the net.Listen/srv.Serve it schedules, never runs.
+		fx.Invoke(func(*http.Server) {}),
+	).Run()
 }
 
-func initConfig() {
-	viper.SetConfigName("config")
-	viper.SetConfigType("yaml")
-	viper.AddConfigPath(".")
-	err := viper.ReadInConfig()
-	if err != nil {
-		panic(fmt.Errorf("fatal error reading env file: %w", err))
+// NewViper loads config.yaml (and matching environment variables) and keeps
+// watching it for changes.
+func NewViper() (*viper.Viper, error) {
+	v := viper.New()
+	v.SetConfigName("config")
+	v.SetConfigType("yaml")
+	v.AddConfigPath(".")
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("fatal error reading env file: %w", err)
 	}
-	viper.AutomaticEnv()
-	viper.WatchConfig()
+	v.AutomaticEnv()
+	v.WatchConfig()
 	log.Println("Config Loaded")
+	return v, nil
 }
 
-func setupRouter() {
-	router = mux.NewRouter()
-	router.Use(
-		middlewares.LogRequest(os.Stdout),
-		middlewares.LogResponse(os.Stdout),
-	)
+// RegisterRoutes attaches the application's routes to router.
+func RegisterRoutes(router *mux.Router) {
 	router.HandleFunc("/", func(rw http.ResponseWriter, r *http.Request) {
 		rw.Write([]byte("Hello World!"))
 	}).Methods("GET")
 	log.Println("Router Loaded")
 }
 
-func startServer() {
-	addr := viper.GetString("SERVER_PORT")
-
+// NewServer builds the *http.Server and registers lifecycle hooks so fx
+// starts it on an fx.Lifecycle OnStart and gracefully drains in-flight
+// requests (bounded by SERVER_DRAIN_TIMEOUT, default 10s) on OnStop, which fx
+// triggers itself on SIGINT/SIGTERM.
+func NewServer(lc fx.Lifecycle, v *viper.Viper, router *mux.Router) *http.Server {
 	srv := &http.Server{
 		Handler:      router,
-		Addr:         addr,
-		WriteTimeout: viper.GetDuration("SERVER_WRITE_TIMEOUT"),
-		ReadTimeout:  viper.GetDuration("SERVER_READ_TIMEOUT"),
+		Addr:         v.GetString("SERVER_PORT"),
+		WriteTimeout: v.GetDuration("SERVER_WRITE_TIMEOUT"),
+		ReadTimeout:  v.GetDuration("SERVER_READ_TIMEOUT"),
 	}
 
-	log.Printf("Server running on: %s", addr)
-
-	if err := srv.ListenAndServe(); err != nil {
-		log.Fatal(err)
+	drainTimeout := v.GetDuration("SERVER_DRAIN_TIMEOUT")
+	if drainTimeout == 0 {
+		drainTimeout = defaultDrainTimeout
 	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			ln, err := net.Listen("tcp", srv.Addr)
+			if err != nil {
+				return err
+			}
+			go srv.Serve(ln)
+			log.Printf("Server running on: %s", srv.Addr)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			ctx, cancel := context.WithTimeout(ctx, drainTimeout)
+			defer cancel()
+			return srv.Shutdown(ctx)
+		},
+	})
+
+	return srv
 }
@@ -0,0 +1,212 @@
+package middlewares
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// defaultMaxCacheableBytes is the largest response body Cache will buffer to
+// serve back on a cache hit before it gives up caching that response.
+const defaultMaxCacheableBytes = 8 << 20 // 8MiB
+
+type cacheEntry struct {
+	status    int
+	header    http.Header
+	body      []byte
+	path      string
+	expiresAt time.Time
+}
+
+// CacheOptions configures Cache.
+type CacheOptions struct {
+	// TTL is how long a cached entry stays valid. Zero means entries never
+	// expire on their own; only eviction or a purge removes them.
+	TTL time.Duration
+	// MaxEntries bounds the number of cached responses. Defaults to 1024.
+	MaxEntries int
+	// MaxCacheableBytes bounds how large a response body may be before Cache
+	// gives up trying to cache it; the client still receives the full
+	// response regardless. Defaults to 8MiB.
+	MaxCacheableBytes int64
+	// KeyFunc derives the cache key for a request. Defaults to method+URL,
+	// extended by the values of the request headers named in Vary.
+	KeyFunc func(r *http.Request) string
+	// Vary lists header names that vary the default cache key alongside
+	// method+URL. Ignored if KeyFunc is set.
+	Vary []string
+	// PurgeMatch reports whether a cached entry for path should be purged
+	// once a mutating request (POST/PUT/PATCH/DELETE) completes with a 2xx
+	// status. It is evaluated once per cached entry against the request, so
+	// a DELETE /users/123 can purge an unrelated GET /users listing by
+	// matching on a path prefix instead of just its own path. Defaults to
+	// purging only the entry whose path exactly matches the request's path.
+	PurgeMatch func(r *http.Request, path string) bool
+	// Logger receives cache-hit/miss metadata. Defaults to discarding it.
+	Logger Logger
+}
+
+func defaultPurgeMatch(r *http.Request, path string) bool {
+	return path == r.URL.Path
+}
+
+func (o *CacheOptions) defaultKeyFunc() func(r *http.Request) string {
+	return func(r *http.Request) string {
+		var b strings.Builder
+		b.WriteString(r.Method)
+		b.WriteByte(' ')
+		b.WriteString(r.URL.String())
+		for _, h := range o.Vary {
+			fmt.Fprintf(&b, "|%s=%s", h, r.Header.Get(h))
+		}
+		return b.String()
+	}
+}
+
+type noopLogger struct{}
+
+func (noopLogger) Log(Level, EventType, map[string]interface{}) {}
+
+// cacheResponseWriter captures the status and, up to max bytes, the body of
+// a response so it can be replayed on a later cache hit. It is independent
+// of logResponseWriter's bounded logging buffer: caching a response that
+// exceeds max must not serve a truncated body back to clients, so it simply
+// gives up retaining the body instead of keeping a partial one.
+type cacheResponseWriter struct {
+	http.ResponseWriter
+	status    int
+	body      bytes.Buffer
+	max       int64
+	oversized bool
+}
+
+func (crw *cacheResponseWriter) Write(b []byte) (int, error) {
+	n, err := crw.ResponseWriter.Write(b)
+	if !crw.oversized {
+		if int64(crw.body.Len()+n) > crw.max {
+			crw.oversized = true
+			crw.body.Reset()
+		} else {
+			crw.body.Write(b[:n])
+		}
+	}
+	return n, err
+}
+
+func (crw *cacheResponseWriter) WriteHeader(status int) {
+	crw.status = status
+	crw.ResponseWriter.WriteHeader(status)
+}
+
+// statusResponseWriter captures only the status of a response, for the
+// purge path which never needs to hold the body in memory.
+type statusResponseWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (srw *statusResponseWriter) WriteHeader(status int) {
+	srw.status = status
+	srw.ResponseWriter.WriteHeader(status)
+}
+
+// Cache caches idempotent GET/HEAD responses (status, headers and body)
+// keyed by method+URL (or a custom KeyFunc) in an LRU backed by
+// hashicorp/golang-lru. A mutating request (POST/PUT/PATCH/DELETE) that
+// completes with a 2xx status purges every cached entry PurgeMatch accepts.
+func Cache(opts CacheOptions) func(next http.Handler) http.Handler {
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = 1024
+	}
+	if opts.MaxCacheableBytes <= 0 {
+		opts.MaxCacheableBytes = defaultMaxCacheableBytes
+	}
+	if opts.KeyFunc == nil {
+		opts.KeyFunc = opts.defaultKeyFunc()
+	}
+	if opts.PurgeMatch == nil {
+		opts.PurgeMatch = defaultPurgeMatch
+	}
+	if opts.Logger == nil {
+		opts.Logger = noopLogger{}
+	}
+
+	cache, err := lru.New(opts.MaxEntries)
+	if err != nil {
+		panic(fmt.Errorf("middlewares: failed to create cache: %w", err))
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				serveAndPurge(cache, opts, w, r, next)
+				return
+			}
+
+			key := opts.KeyFunc(r)
+			if entry, ok := cache.Get(key); ok {
+				if e := entry.(*cacheEntry); e.expiresAt.IsZero() || time.Now().Before(e.expiresAt) {
+					opts.Logger.Log(LevelInfo, ResponseType, map[string]interface{}{
+						"request_id": RequestIDFromContext(r.Context()),
+						"cache":      "hit",
+						"key":        key,
+					})
+					for k, vals := range e.header {
+						for _, v := range vals {
+							w.Header().Add(k, v)
+						}
+					}
+					w.WriteHeader(e.status)
+					w.Write(e.body)
+					return
+				}
+				cache.Remove(key)
+			}
+
+			opts.Logger.Log(LevelInfo, ResponseType, map[string]interface{}{
+				"request_id": RequestIDFromContext(r.Context()),
+				"cache":      "miss",
+				"key":        key,
+			})
+
+			crw := &cacheResponseWriter{ResponseWriter: w, status: http.StatusOK, max: opts.MaxCacheableBytes}
+			next.ServeHTTP(crw, r)
+
+			if crw.status >= 200 && crw.status < 300 && !crw.oversized {
+				entry := &cacheEntry{
+					status: crw.status,
+					header: crw.Header().Clone(),
+					body:   append([]byte(nil), crw.body.Bytes()...),
+					path:   r.URL.Path,
+				}
+				if opts.TTL > 0 {
+					entry.expiresAt = time.Now().Add(opts.TTL)
+				}
+				cache.Add(key, entry)
+			}
+		})
+	}
+}
+
+func serveAndPurge(cache *lru.Cache, opts CacheOptions, w http.ResponseWriter, r *http.Request, next http.Handler) {
+	srw := &statusResponseWriter{ResponseWriter: w, status: http.StatusOK}
+	next.ServeHTTP(srw, r)
+
+	if srw.status < 200 || srw.status >= 300 {
+		return
+	}
+
+	for _, key := range cache.Keys() {
+		entry, ok := cache.Peek(key)
+		if !ok {
+			continue
+		}
+		if e, ok := entry.(*cacheEntry); ok && opts.PurgeMatch(r, e.path) {
+			cache.Remove(key)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func countingHandler(body string) (http.Handler, *int) {
+	calls := 0
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write([]byte(body))
+	}), &calls
+}
+
+func TestCacheServesHitWithoutCallingHandlerAgain(t *testing.T) {
+	next, calls := countingHandler("hello")
+	handler := Cache(CacheOptions{})(next)
+
+	req := httptest.NewRequest(http.MethodGet, "/items", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if *calls != 1 {
+		t.Fatalf("handler called %d times, want 1", *calls)
+	}
+	if rec.Body.String() != "hello" {
+		t.Fatalf("body = %q, want %q", rec.Body.String(), "hello")
+	}
+}
+
+func TestCacheEntryExpiresAfterTTL(t *testing.T) {
+	next, calls := countingHandler("hello")
+	handler := Cache(CacheOptions{TTL: time.Millisecond})(next)
+
+	req := httptest.NewRequest(http.MethodGet, "/items", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	time.Sleep(5 * time.Millisecond)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if *calls != 2 {
+		t.Fatalf("handler called %d times, want 2 (cache entry should have expired)", *calls)
+	}
+}
+
+func TestCachePurgesRelatedEntriesOnMutation(t *testing.T) {
+	list, listCalls := countingHandler("list")
+	router := http.NewServeMux()
+	router.Handle("/users", list)
+	router.HandleFunc("/users/123", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	handler := Cache(CacheOptions{
+		PurgeMatch: func(r *http.Request, path string) bool {
+			return strings.HasPrefix(r.URL.Path, "/users") && strings.HasPrefix(path, "/users")
+		},
+	})(router)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, "/users/123", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users", nil))
+
+	if *listCalls != 2 {
+		t.Fatalf("GET /users handler called %d times, want 2 (DELETE /users/123 should have purged the listing)", *listCalls)
+	}
+}
+
+func TestCacheSkipsOversizedBodyWithoutCorruptingReplay(t *testing.T) {
+	big := strings.Repeat("a", 2048)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(big))
+	})
+	handler := Cache(CacheOptions{MaxCacheableBytes: 1024})(next)
+
+	req := httptest.NewRequest(http.MethodGet, "/big", nil)
+	first := httptest.NewRecorder()
+	handler.ServeHTTP(first, req)
+	second := httptest.NewRecorder()
+	handler.ServeHTTP(second, req)
+
+	if first.Body.String() != big || second.Body.String() != big {
+		t.Fatalf("got bodies of length %d and %d, want both %d", first.Body.Len(), second.Body.Len(), len(big))
+	}
+}
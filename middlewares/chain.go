@@ -0,0 +1,58 @@
+package middlewares
+
+import "net/http"
+
+// Chain composes a sequence of middlewares so they can be built once and
+// reused across many mux.Router subtrees without repeating router.Use(...)
+// calls. Each middleware stays a plain func(http.Handler) http.Handler, so
+// it remains assignable to mux.MiddlewareFunc and the two styles interop.
+type Chain struct {
+	middlewares []func(http.Handler) http.Handler
+}
+
+// New creates a Chain from the given middlewares, applied in the order they
+// are given: the first middleware wraps all the others.
+func New(middlewares ...func(http.Handler) http.Handler) Chain {
+	return Chain{middlewares: append([]func(http.Handler) http.Handler{}, middlewares...)}
+}
+
+// Append extends the chain, adding the given middlewares as the last ones in
+// the request flow.
+func (c Chain) Append(middlewares ...func(http.Handler) http.Handler) Chain {
+	newChain := make([]func(http.Handler) http.Handler, 0, len(c.middlewares)+len(middlewares))
+	newChain = append(newChain, c.middlewares...)
+	newChain = append(newChain, middlewares...)
+	return Chain{middlewares: newChain}
+}
+
+// Extend extends the chain by adding the given chain as the last one in the
+// request flow.
+func (c Chain) Extend(chain Chain) Chain {
+	return c.Append(chain.middlewares...)
+}
+
+// Then chains the middleware and returns the final http.Handler.
+//
+//	New(m1, m2, m3).Then(h)
+//
+// is equivalent to:
+//
+//	m1(m2(m3(h)))
+func (c Chain) Then(h http.Handler) http.Handler {
+	if h == nil {
+		h = http.DefaultServeMux
+	}
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		h = c.middlewares[i](h)
+	}
+	return h
+}
+
+// ThenFunc works like Then, but takes a http.HandlerFunc instead of a
+// http.Handler.
+func (c Chain) ThenFunc(fn http.HandlerFunc) http.Handler {
+	if fn == nil {
+		return c.Then(nil)
+	}
+	return c.Then(fn)
+}
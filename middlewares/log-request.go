@@ -1,33 +1,135 @@
 package middlewares
 
 import (
-	"encoding/json"
+	"bytes"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"strings"
 )
 
-func LogRequest(l io.Writer) func(next http.Handler) http.Handler {
+// defaultMaxBodyBytes is the largest request body LogRequest will buffer for
+// logging before flagging the entry as truncated.
+const defaultMaxBodyBytes = 1 << 20 // 1MiB
+
+type requestLogConfig struct {
+	level        Level
+	maxBodyBytes int64
+	skipBody     func(r *http.Request) bool
+}
+
+// RequestLogOption configures LogRequest.
+type RequestLogOption func(*requestLogConfig)
+
+// WithRequestLevel overrides the severity request-received events are logged at.
+func WithRequestLevel(level Level) RequestLogOption {
+	return func(c *requestLogConfig) { c.level = level }
+}
+
+// WithMaxBodyBytes caps how much of the request body LogRequest buffers for
+// logging. A body whose declared Content-Length exceeds this is delivered to
+// the handler untouched, with capture skipped and the log entry flagged with
+// body_truncated; a body of undeclared length that turns out larger is
+// rejected with 413 (see LogRequest). Defaults to 1MiB.
+func WithMaxBodyBytes(n int64) RequestLogOption {
+	return func(c *requestLogConfig) { c.maxBodyBytes = n }
+}
+
+// WithSkipBody replaces the default predicate deciding which requests have
+// their body capture skipped entirely. The default skips multipart/form-data
+// and application/octet-stream content types.
+func WithSkipBody(skip func(r *http.Request) bool) RequestLogOption {
+	return func(c *requestLogConfig) { c.skipBody = skip }
+}
+
+func defaultSkipBody(r *http.Request) bool {
+	ct := r.Header.Get("Content-Type")
+	return strings.HasPrefix(ct, "multipart/form-data") || strings.HasPrefix(ct, "application/octet-stream")
+}
+
+// cappedWriter retains only the first max bytes written to it, reporting
+// every write as fully successful so tee'ing the full request body through
+// it never truncates the stream itself - only how much of it gets logged.
+type cappedWriter struct {
+	buf       bytes.Buffer
+	max       int64
+	truncated bool
+}
+
+func (c *cappedWriter) Write(p []byte) (int, error) {
+	remaining := c.max - int64(c.buf.Len())
+	if remaining <= 0 {
+		if len(p) > 0 {
+			c.truncated = true
+		}
+		return len(p), nil
+	}
+	take := int64(len(p))
+	if take > remaining {
+		take = remaining
+		c.truncated = true
+	}
+	c.buf.Write(p[:take])
+	return len(p), nil
+}
+
+// LogRequest logs each incoming request through logger. It generates a
+// request ID, stores it on the request context so LogResponse (and
+// downstream handlers) can correlate their own log entries with it, and
+// captures a bounded copy of the body for the log entry without preventing
+// the handler from reading the full stream.
+//
+// The replay copy handlers read via r.Body is itself bounded by
+// maxBodyBytes via http.MaxBytesReader - not just what gets logged - so a
+// body of unknown length (no Content-Length, e.g. chunked transfer-encoding)
+// can't be used to buffer an unbounded amount of memory. A request whose
+// body turns out to exceed the cap is rejected with 413 rather than served
+// a silently truncated or shifted body.
+func LogRequest(logger Logger, opts ...RequestLogOption) func(next http.Handler) http.Handler {
+	cfg := requestLogConfig{
+		level:        LevelInfo,
+		maxBodyBytes: defaultMaxBodyBytes,
+		skipBody:     defaultSkipBody,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			body, _ := ioutil.ReadAll(r.Body)
-			request, _ := json.Marshal(struct {
-				Host   string
-				URL    string
-				Method string
-				Header http.Header
-				Status int
-				Body   []byte
-				Type   string
-			}{
-				Host:   r.Host,
-				URL:    r.URL.String(),
-				Method: r.Method,
-				Header: r.Header,
-				Body:   body,
-				Type:   RequestType,
-			})
-			l.Write(request)
+			requestID := newRequestID()
+			r = r.WithContext(withRequestID(r.Context(), requestID))
+
+			fields := map[string]interface{}{
+				"request_id":     requestID,
+				"host":           r.Host,
+				"url":            r.URL.String(),
+				"method":         r.Method,
+				"remote_addr":    r.RemoteAddr,
+				"user_agent":     r.UserAgent(),
+				"content_length": r.ContentLength,
+			}
+
+			oversized := r.ContentLength > 0 && r.ContentLength > cfg.maxBodyBytes
+			if r.Body != nil && r.Body != http.NoBody && !cfg.skipBody(r) && !oversized {
+				capture := &cappedWriter{max: cfg.maxBodyBytes}
+				limited := http.MaxBytesReader(w, r.Body, cfg.maxBodyBytes)
+				body, err := ioutil.ReadAll(io.TeeReader(limited, capture))
+				if err != nil {
+					fields["body_truncated"] = true
+					logger.Log(cfg.level, RequestType, fields)
+					http.Error(w, "http: request body too large", http.StatusRequestEntityTooLarge)
+					return
+				}
+				fields["body_truncated"] = capture.truncated
+				fields["body"] = capture.buf.String()
+				r.Body = ioutil.NopCloser(bytes.NewReader(body))
+			} else if r.Body != nil && r.Body != http.NoBody {
+				fields["body_truncated"] = true
+			}
+
+			logger.Log(cfg.level, RequestType, fields)
+
 			next.ServeHTTP(w, r)
 		})
 	}
@@ -1,20 +1,38 @@
 package middlewares
 
 import (
-	"encoding/json"
-	"io"
+	"bufio"
+	"errors"
+	"net"
 	"net/http"
+	"time"
 )
 
+// defaultMaxResponseBodyBytes is the largest response body LogResponse will
+// buffer for logging before it stops retaining further bytes.
+const defaultMaxResponseBodyBytes = 1 << 20 // 1MiB
+
 type logResponseWriter struct {
 	http.ResponseWriter
-	Status int
-	Body   []byte
+	Status       int
+	Body         []byte
+	Bytes        int
+	maxBodyBytes int64
 }
 
 func (lrw *logResponseWriter) Write(b []byte) (int, error) {
-	lrw.Body = b
-	return lrw.ResponseWriter.Write(b)
+	n, err := lrw.ResponseWriter.Write(b)
+	lrw.Bytes += n
+
+	if remaining := lrw.maxBodyBytes - int64(len(lrw.Body)); remaining > 0 {
+		take := int64(n)
+		if take > remaining {
+			take = remaining
+		}
+		lrw.Body = append(lrw.Body, b[:take]...)
+	}
+
+	return n, err
 }
 
 func (lrw *logResponseWriter) WriteHeader(status int) {
@@ -22,34 +40,87 @@ func (lrw *logResponseWriter) WriteHeader(status int) {
 	lrw.ResponseWriter.WriteHeader(status)
 }
 
-func LogResponse(l io.Writer) func(next http.Handler) http.Handler {
+// Hijack implements http.Hijacker by delegating to the wrapped
+// ResponseWriter, so protocol upgrades (e.g. gorilla/websocket) still work
+// when LogResponse is installed.
+func (lrw *logResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := lrw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("middlewares: underlying ResponseWriter does not support http.Hijacker")
+	}
+	return hijacker.Hijack()
+}
+
+// Flush implements http.Flusher by delegating to the wrapped ResponseWriter,
+// so streamed responses (e.g. SSE) still flush promptly.
+func (lrw *logResponseWriter) Flush() {
+	if flusher, ok := lrw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Push implements http.Pusher by delegating to the wrapped ResponseWriter,
+// for servers that support HTTP/2 server push.
+func (lrw *logResponseWriter) Push(target string, opts *http.PushOptions) error {
+	pusher, ok := lrw.ResponseWriter.(http.Pusher)
+	if !ok {
+		return http.ErrNotSupported
+	}
+	return pusher.Push(target, opts)
+}
+
+type responseLogConfig struct {
+	level        Level
+	maxBodyBytes int64
+}
+
+// ResponseLogOption configures LogResponse.
+type ResponseLogOption func(*responseLogConfig)
+
+// WithResponseLevel overrides the severity response-emitted events are logged at.
+func WithResponseLevel(level Level) ResponseLogOption {
+	return func(c *responseLogConfig) { c.level = level }
+}
+
+// WithResponseMaxBodyBytes caps how much of the response body LogResponse
+// buffers for logging. Defaults to 1MiB; the client still receives the full
+// response regardless of this cap.
+func WithResponseMaxBodyBytes(n int64) ResponseLogOption {
+	return func(c *responseLogConfig) { c.maxBodyBytes = n }
+}
+
+// LogResponse logs each outgoing response through logger, including the
+// latency since the request came in and the request ID LogRequest attached
+// to the context, so the two log entries can be correlated. The wrapped
+// ResponseWriter always implements Hijacker, Flusher and Pusher and delegates
+// them to the underlying ResponseWriter, so WebSocket upgrades and streamed
+// responses keep working with the middleware installed. Where the underlying
+// ResponseWriter lacks one of them, Hijack returns an error, Flush does
+// nothing and Push returns http.ErrNotSupported.
+func LogResponse(logger Logger, opts ...ResponseLogOption) func(next http.Handler) http.Handler {
+	cfg := responseLogConfig{level: LevelInfo, maxBodyBytes: defaultMaxResponseBodyBytes}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			lrw := &logResponseWriter{
-				ResponseWriter: w,
-			}
-
-			next.ServeHTTP(w, r)
-
-			response, _ := json.Marshal(struct {
-				Host   string
-				URL    string
-				Method string
-				Header http.Header
-				Status int
-				Body   []byte
-				Type   string
-			}{
-				Host:   r.Host,
-				URL:    r.URL.String(),
-				Method: r.Method,
-				Header: lrw.Header(),
-				Status: lrw.Status,
-				Body:   lrw.Body,
-				Type:   ResponseType,
-			})
+			start := time.Now()
+			lrw := &logResponseWriter{ResponseWriter: w, Status: http.StatusOK, maxBodyBytes: cfg.maxBodyBytes}
+
+			next.ServeHTTP(lrw, r)
 
-			l.Write(response)
+			logger.Log(cfg.level, ResponseType, map[string]interface{}{
+				"request_id":     RequestIDFromContext(r.Context()),
+				"host":           r.Host,
+				"url":            r.URL.String(),
+				"method":         r.Method,
+				"status":         lrw.Status,
+				"bytes":          lrw.Bytes,
+				"body":           string(lrw.Body),
+				"body_truncated": int64(lrw.Bytes) > cfg.maxBodyBytes,
+				"latency_ms":     time.Since(start).Milliseconds(),
+			})
 		})
 	}
 }
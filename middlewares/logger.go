@@ -0,0 +1,94 @@
+package middlewares
+
+import (
+	"io"
+
+	"github.com/rs/zerolog"
+	"go.uber.org/zap"
+)
+
+// EventType identifies which stage of the request lifecycle a log entry
+// describes.
+type EventType string
+
+const (
+	RequestType  EventType = "request"
+	ResponseType EventType = "response"
+	PanicType    EventType = "panic"
+)
+
+// Level is the severity a Logger is asked to emit an event at.
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// Logger is the structured logging sink used by the request, response and
+// panic middlewares. Implementations are provided for zerolog and zap;
+// callers may supply their own to redirect or filter log output.
+type Logger interface {
+	Log(level Level, event EventType, fields map[string]interface{})
+}
+
+// ZerologLogger adapts a zerolog.Logger to the Logger interface.
+type ZerologLogger struct {
+	logger zerolog.Logger
+}
+
+// NewZerologLogger returns a Logger that writes JSON lines to w via zerolog.
+func NewZerologLogger(w io.Writer) *ZerologLogger {
+	return &ZerologLogger{logger: zerolog.New(w).With().Timestamp().Logger()}
+}
+
+func (l *ZerologLogger) Log(level Level, event EventType, fields map[string]interface{}) {
+	var evt *zerolog.Event
+	switch level {
+	case LevelDebug:
+		evt = l.logger.Debug()
+	case LevelWarn:
+		evt = l.logger.Warn()
+	case LevelError:
+		evt = l.logger.Error()
+	default:
+		evt = l.logger.Info()
+	}
+
+	evt = evt.Str("event", string(event))
+	for k, v := range fields {
+		evt = evt.Interface(k, v)
+	}
+	evt.Send()
+}
+
+// ZapLogger adapts a *zap.Logger to the Logger interface.
+type ZapLogger struct {
+	logger *zap.Logger
+}
+
+// NewZapLogger returns a Logger backed by an existing *zap.Logger.
+func NewZapLogger(logger *zap.Logger) *ZapLogger {
+	return &ZapLogger{logger: logger}
+}
+
+func (l *ZapLogger) Log(level Level, event EventType, fields map[string]interface{}) {
+	zfields := make([]zap.Field, 0, len(fields)+1)
+	zfields = append(zfields, zap.String("event", string(event)))
+	for k, v := range fields {
+		zfields = append(zfields, zap.Any(k, v))
+	}
+
+	switch level {
+	case LevelDebug:
+		l.logger.Debug("", zfields...)
+	case LevelWarn:
+		l.logger.Warn("", zfields...)
+	case LevelError:
+		l.logger.Error("", zfields...)
+	default:
+		l.logger.Info("", zfields...)
+	}
+}
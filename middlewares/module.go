@@ -0,0 +1,30 @@
+package middlewares
+
+import (
+	"os"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/fx"
+)
+
+// Module provides a default Logger and registers the LogRequest, LogResponse
+// and Recover middlewares on the *mux.Router, so consumers of this package
+// can fx.New(middlewares.Module, ...) to get them wired up automatically
+// instead of calling router.Use(...) by hand.
+var Module = fx.Module("middlewares",
+	fx.Provide(
+		func() Logger { return NewZerologLogger(os.Stdout) },
+	),
+	fx.Invoke(registerMiddlewares),
+)
+
+func registerMiddlewares(router *mux.Router, logger Logger) {
+	// LogRequest must run before Recover so the request ID it attaches to
+	// the context is visible to Recover's panic log entries; LogResponse
+	// sits between them so a panic's error response still gets captured.
+	router.Use(
+		LogRequest(logger),
+		LogResponse(logger),
+		Recover(WithRecoverLogger(logger)),
+	)
+}
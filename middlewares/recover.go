@@ -0,0 +1,118 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"runtime/debug"
+)
+
+// PanicSink receives panics recovered by Recover, e.g. to forward them to an
+// external error-tracking service such as Sentry.
+type PanicSink interface {
+	Recover(err interface{}, stack []byte)
+}
+
+type recoverConfig struct {
+	logger       Logger
+	level        Level
+	status       int
+	errorHandler func(w http.ResponseWriter, r *http.Request, err interface{})
+	repanic      bool
+	sink         PanicSink
+}
+
+// RecoverOption configures Recover.
+type RecoverOption func(*recoverConfig)
+
+// WithRecoverLogger sets the Logger panics are reported through. Defaults to
+// a ZerologLogger writing to os.Stderr.
+func WithRecoverLogger(logger Logger) RecoverOption {
+	return func(c *recoverConfig) { c.logger = logger }
+}
+
+// WithRecoverLevel overrides the severity panic events are logged at.
+func WithRecoverLevel(level Level) RecoverOption {
+	return func(c *recoverConfig) { c.level = level }
+}
+
+// WithErrorStatus overrides the HTTP status written to the client. Defaults
+// to http.StatusInternalServerError.
+func WithErrorStatus(status int) RecoverOption {
+	return func(c *recoverConfig) { c.status = status }
+}
+
+// WithErrorHandler replaces the default JSON error body with a custom
+// callback, for callers that want to control the response shape.
+func WithErrorHandler(handler func(w http.ResponseWriter, r *http.Request, err interface{})) RecoverOption {
+	return func(c *recoverConfig) { c.errorHandler = handler }
+}
+
+// WithRepanic re-panics after logging and responding, so the panic still
+// reaches http.Server.ErrorLog (or an outer recover) instead of being fully
+// swallowed.
+func WithRepanic() RecoverOption {
+	return func(c *recoverConfig) { c.repanic = true }
+}
+
+// WithPanicSink forwards every recovered panic to sink, e.g. a Sentry client.
+func WithPanicSink(sink PanicSink) RecoverOption {
+	return func(c *recoverConfig) { c.sink = sink }
+}
+
+// Recover wraps next in a defer/recover. A recovered panic is logged with
+// its stack trace through the configured Logger, optionally forwarded to a
+// PanicSink, and answered with a JSON error body instead of killing the
+// connection with no log line.
+func Recover(opts ...RecoverOption) func(next http.Handler) http.Handler {
+	cfg := recoverConfig{
+		logger: NewZerologLogger(os.Stderr),
+		level:  LevelError,
+		status: http.StatusInternalServerError,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.errorHandler == nil {
+		cfg.errorHandler = func(w http.ResponseWriter, r *http.Request, err interface{}) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(cfg.status)
+			json.NewEncoder(w).Encode(struct {
+				Error string `json:"error"`
+			}{Error: fmt.Sprintf("%v", err)})
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				err := recover()
+				if err == nil {
+					return
+				}
+
+				stack := debug.Stack()
+				cfg.logger.Log(cfg.level, PanicType, map[string]interface{}{
+					"request_id": RequestIDFromContext(r.Context()),
+					"host":       r.Host,
+					"url":        r.URL.String(),
+					"method":     r.Method,
+					"error":      fmt.Sprintf("%v", err),
+					"stack":      string(stack),
+				})
+
+				if cfg.sink != nil {
+					cfg.sink.Recover(err, stack)
+				}
+
+				cfg.errorHandler(w, r, err)
+
+				if cfg.repanic {
+					panic(err)
+				}
+			}()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRecoverWritesDefaultErrorResponse(t *testing.T) {
+	handler := Recover(WithRecoverLogger(noopLogger{}))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	if rec.Body.Len() == 0 {
+		t.Fatal("expected a JSON error body, got none")
+	}
+}
+
+func TestRecoverRepanics(t *testing.T) {
+	handler := Recover(WithRecoverLogger(noopLogger{}), WithRepanic())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected the panic to propagate past Recover")
+		}
+	}()
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+type sinkRecorder struct {
+	called bool
+}
+
+func (s *sinkRecorder) Recover(err interface{}, stack []byte) {
+	s.called = true
+}
+
+func TestRecoverForwardsToSink(t *testing.T) {
+	sink := &sinkRecorder{}
+	handler := Recover(WithRecoverLogger(noopLogger{}), WithPanicSink(sink))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if !sink.called {
+		t.Fatal("expected the PanicSink to be invoked")
+	}
+}